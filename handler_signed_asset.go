@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/filestore"
+)
+
+// handlerGetSignedAsset serves objects out of the LocalDiskFileStore when
+// cfg.fileStore is backed by disk instead of S3. It's the dev-mode stand-in
+// for S3's presigned GET URLs: the key path segment and the sig and exp query
+// params are the same ones LocalDiskFileStore.PresignGetURL handed out.
+func (cfg *apiConfig) handlerGetSignedAsset(w http.ResponseWriter, r *http.Request) {
+	localStore, ok := cfg.fileStore.(*filestore.LocalDiskFileStore)
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Signed local assets aren't enabled", nil)
+		return
+	}
+
+	key := r.PathValue("key")
+	sig := r.URL.Query().Get("sig")
+	exp := r.URL.Query().Get("exp")
+
+	if !localStore.VerifySignedURL(key, sig, exp) {
+		respondWithError(w, http.StatusForbidden, "Invalid or expired signature", nil)
+		return
+	}
+
+	file, err := localStore.GetObject(r.Context(), key)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find asset", err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't stream asset", err)
+		return
+	}
+}
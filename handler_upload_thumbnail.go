@@ -2,10 +2,7 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"time"
 
 	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
 	"github.com/google/uuid"
@@ -62,16 +59,10 @@ func (cfg *apiConfig) handlerUploadThumbnail(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Save the file to disk
+	// Upload the file via the configured FileStore (S3 or local disk)
 	assetPath := getAssetPath(mediaType)
-	assetDiskPath := cfg.getAssetDiskPath(assetPath)
-	dst, err := os.Create(assetDiskPath)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't create file on server", err)
-		return
-	}
-	defer dst.Close()
-	if _, err = io.Copy(dst, file); err != nil {
+	key := fmt.Sprintf("thumbnails/%s", assetPath)
+	if err := cfg.fileStore.PutObject(r.Context(), key, file, mediaType); err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't save file", err)
 		return
 	}
@@ -88,16 +79,22 @@ func (cfg *apiConfig) handlerUploadThumbnail(w http.ResponseWriter, r *http.Requ
 		respondWithError(w, http.StatusUnauthorized, "User is not the owner of the video", nil)
 		return
 	}
-	// Update the video in the database
-	thumbnailUrl := cfg.getAssetURL(assetPath)
-	video.ThumbnailURL = &thumbnailUrl
-	video.UpdatedAt = time.Now() // not currently supported by UpdateVideo
+	// Update the video in the database. Store the bare key rather than a
+	// presigned URL - like VideoKey, it's re-signed on every read by
+	// dbVideoToSignedVideo instead of going stale after thumbnailURLTTL.
+	video.ThumbnailKey = &key
 	err = cfg.db.UpdateVideo(video)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't update video in database", err)
 		return
 	}
 
+	signedVideo, err := cfg.signThumbnailURL(r.Context(), video)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't generate thumbnail URL", err)
+		return
+	}
+
 	// Respond with the updated video
-	respondWithJSON(w, http.StatusOK, video)
+	respondWithJSON(w, http.StatusOK, signedVideo)
 }
@@ -10,11 +10,8 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
 	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
 	"github.com/google/uuid"
@@ -85,8 +82,22 @@ func (cfg *apiConfig) handlerUploadVideo(w http.ResponseWriter, r *http.Request)
 	defer os.Remove(tempFile.Name())
 	defer tempFile.Close() // defer is LIFO, so the file will be closed before being removed
 
-	_, err = io.Copy(tempFile, videoFile)
-	if err != nil {
+	// Wrap the uploaded file's reader so each chunk copied into tempFile is
+	// published to this video's SSE progress stream (see
+	// handlerVideoUploadProgress). r.FormFile has already read the whole
+	// request body by this point, so these samples track that copy, not the
+	// client's transfer over the network.
+	trackedReader := newProgressReader(videoFile, fileHeader.Size, func(total, exp int64) {
+		percent := 0.0
+		if exp > 0 {
+			percent = float64(total) / float64(exp) * 100
+		}
+		cfg.progress.publish(Progress{VideoID: videoID, Phase: progressPhaseUploading, BytesRead: total, TotalBytes: exp, Percent: percent})
+	})
+
+	_, err = io.Copy(tempFile, trackedReader)
+	if err != nil {
+		cfg.progress.publish(Progress{VideoID: videoID, Phase: progressPhaseError})
 		respondWithError(w, http.StatusInternalServerError, "Couldn't save video", err)
 		return
 	}
@@ -96,70 +107,32 @@ func (cfg *apiConfig) handlerUploadVideo(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get the aspect ratio of the video
-	aspectRatio, err := getVideoAspectRatio(tempFile.Name())
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't get video aspect ratio", err)
+	// Persist the upload to a durable temp file the worker can read after this
+	// handler returns; tempFile itself is removed by the defer above.
+	jobFilePath := tempFile.Name() + ".hls-source"
+	if err := os.Rename(tempFile.Name(), jobFilePath); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't stage video for transcoding", err)
 		return
 	}
-	directory := ""
-	switch aspectRatio {
-	case "9:16":
-		directory = "portrait"
-	case "16:9":
-		directory = "landscape"
-	default:
-		directory = "other"
-	}
 
-	// Generate a random S3 file key
-	s3FileKey := getAssetPath(mediaType)
-	s3FileKey = filepath.Join(directory, s3FileKey)
-
-	// Process the video for fast start
-	processedFilePath, err := processVideoForFastStart(tempFile.Name())
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't process video for fast start", err)
-		return
-	}
-	defer os.Remove(processedFilePath)
-
-	// Open the processed video file
-	processedFile, err := os.Open(processedFilePath)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't open processed video file", err)
+	video.TranscodeStatus = string(transcodeStatusProcessing)
+	if err := cfg.db.UpdateVideo(video); err != nil {
+		os.Remove(jobFilePath)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update video in database", err)
 		return
 	}
-	defer processedFile.Close()
 
-	// Create the S3 PutObjectInput
-	s3PutObjectInput := &s3.PutObjectInput{
-		Bucket:      &cfg.s3Bucket,
-		Key:         &s3FileKey,
-		Body:        processedFile,
-		ContentType: &mediaType,
-	}
-	_, err = cfg.s3Client.PutObject(r.Context(), s3PutObjectInput)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't upload video to S3", err)
-		return
-	}
+	// The client->server copy is done, but the slow part - transcoding to HLS
+	// and uploading the rendered ladder - is still ahead; runTranscodeJob
+	// publishes the rest of this video's progress samples.
+	cfg.progress.publish(Progress{VideoID: videoID, Phase: progressPhaseTranscoding})
 
-	// Update the video metadata in the database with the presigned URL
-	s3VideoURL := fmt.Sprintf("%s,%s", cfg.s3Bucket, s3FileKey)
-	video.VideoURL = &s3VideoURL
-	err = cfg.db.UpdateVideo(video)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't update video in database", err)
-		return
-	}
-	presignedVideo, err := cfg.dbVideoToSignedVideo(video)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't convert video to signed video", err)
-		return
-	}
+	cfg.jobQueue.enqueue(transcodeJob{videoID: videoID, inputFilePath: jobFilePath})
 
-	respondWithJSON(w, http.StatusOK, presignedVideo)
+	respondWithJSON(w, http.StatusAccepted, struct {
+		VideoID string `json:"video_id"`
+		Status  string `json:"status"`
+	}{VideoID: videoID.String(), Status: string(transcodeStatusProcessing)})
 }
 
 func getVideoAspectRatio(filePath string) (string, error) {
@@ -210,60 +183,33 @@ func getVideoAspectRatio(filePath string) (string, error) {
 	return "other", nil
 }
 
-func processVideoForFastStart(inputFilePath string) (string, error) {
-	processedFilePath := fmt.Sprintf("%s.processing", inputFilePath)
-
-	cmd := exec.Command("ffmpeg",
-		"-i", inputFilePath,
-		"-movflags", "faststart",
-		"-codec", "copy",
-		"-f", "mp4",
-		processedFilePath,
-	)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("couldn't process video: %s, %v", stderr.String(), err)
-	}
-
-	fileInfo, err := os.Stat(processedFilePath)
+func (cfg *apiConfig) dbVideoToSignedVideo(ctx context.Context, video database.Video) (database.Video, error) {
+	video, err := cfg.signVideoURL(ctx, video)
 	if err != nil {
-		return "", fmt.Errorf("couldn't stat processed file: %v", err)
-	}
-	if fileInfo.Size() == 0 {
-		return "", fmt.Errorf("processed file is empty")
+		return video, err
 	}
-
-	return processedFilePath, nil
+	return cfg.signThumbnailURL(ctx, video)
 }
 
-func generatePresignedURL(s3Client *s3.Client, bucket, key string, expireTime time.Duration) (string, error) {
-	presignClient := s3.NewPresignClient(s3Client)
-	params := s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &key,
-	}
-	presignedURL, err := presignClient.PresignGetObject(context.Background(), &params, s3.WithPresignExpires(expireTime))
-	if err != nil {
-		return "", fmt.Errorf("couldn't generate presigned URL: %w", err)
-	}
-	return presignedURL.URL, nil
-}
-
-func (cfg *apiConfig) dbVideoToSignedVideo(video database.Video) (database.Video, error) {
-	if video.VideoURL == nil {
+func (cfg *apiConfig) signVideoURL(ctx context.Context, video database.Video) (database.Video, error) {
+	// HLS uploads are keyed by their master playlist instead of a single
+	// VideoURL. Only the master playlist is presigned: it lists the rendition
+	// playlists by relative path, so those and their segments are requested
+	// without a signature of their own.
+	if video.VideoManifestKey != nil {
+		presignedURL, err := cfg.fileStore.PresignGetURL(ctx, *video.VideoManifestKey, 10*time.Minute)
+		if err != nil {
+			return video, fmt.Errorf("couldn't generate presigned URL for manifest: %w", err)
+		}
+		video.VideoURL = &presignedURL
 		return video, nil
 	}
 
-	urlSlice := strings.Split(*video.VideoURL, ",")
-	if len(urlSlice) != 2 {
-		return video, fmt.Errorf("video URL is not in the expected format: %s", *video.VideoURL)
+	if video.VideoKey == "" {
+		return video, nil
 	}
 
-	videoBucket := urlSlice[0]
-	videoKey := urlSlice[1]
-	presignedURL, err := generatePresignedURL(cfg.s3Client, videoBucket, videoKey, 10*time.Minute)
+	presignedURL, err := cfg.fileStore.PresignGetURL(ctx, video.VideoKey, 10*time.Minute)
 	if err != nil {
 		return video, fmt.Errorf("couldn't generate presigned URL for video: %w", err)
 	}
@@ -271,3 +217,20 @@ func (cfg *apiConfig) dbVideoToSignedVideo(video database.Video) (database.Video
 	video.VideoURL = &presignedURL
 	return video, nil
 }
+
+// signThumbnailURL re-presigns ThumbnailURL from the stored ThumbnailKey on
+// every read, the same way signVideoURL does for VideoURL. Thumbnails used to
+// store a single presigned URL with a thumbnailURLTTL of its own, which meant
+// it quietly expired with no way to refresh it short of a re-upload.
+func (cfg *apiConfig) signThumbnailURL(ctx context.Context, video database.Video) (database.Video, error) {
+	if video.ThumbnailKey == nil {
+		return video, nil
+	}
+
+	presignedURL, err := cfg.fileStore.PresignGetURL(ctx, *video.ThumbnailKey, thumbnailURLTTL)
+	if err != nil {
+		return video, fmt.Errorf("couldn't generate presigned URL for thumbnail: %w", err)
+	}
+	video.ThumbnailURL = &presignedURL
+	return video, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
+	"github.com/google/uuid"
+	"github.com/kkdai/youtube/v2"
+)
+
+type fromYouTubeRequest struct {
+	YouTubeID string `json:"youtube_id"`
+}
+
+// handlerCreateVideoFromYouTube re-hosts a public YouTube video through the
+// same pipeline a direct upload goes through: it downloads the highest
+// quality progressive MP4 server-side, stages it for the transcode worker,
+// and copies over the source's title/description/duration/thumbnail.
+func (cfg *apiConfig) handlerCreateVideoFromYouTube(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	var req fromYouTubeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse request body", err)
+		return
+	}
+	if req.YouTubeID == "" {
+		respondWithError(w, http.StatusBadRequest, "Missing youtube_id", nil)
+		return
+	}
+
+	ytClient := youtube.Client{}
+	ytVideo, err := ytClient.GetVideo(req.YouTubeID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't look up YouTube video", err)
+		return
+	}
+
+	format, err := highestQualityProgressiveFormat(ytVideo.Formats)
+	if err != nil {
+		respondWithError(w, http.StatusUnprocessableEntity, "Couldn't find a progressive MP4 stream", err)
+		return
+	}
+
+	stream, _, err := ytClient.GetStream(ytVideo, format)
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, "Couldn't fetch YouTube stream", err)
+		return
+	}
+	defer stream.Close()
+
+	tempFile, err := os.CreateTemp("", "tubely-youtube.mp4")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create temporary video file", err)
+		return
+	}
+	defer tempFile.Close()
+
+	if _, err := io.Copy(tempFile, stream); err != nil {
+		os.Remove(tempFile.Name())
+		respondWithError(w, http.StatusBadGateway, "Couldn't download YouTube stream", err)
+		return
+	}
+
+	video := database.Video{}
+	video.ID = uuid.New()
+	video.UserID = userID
+	video.Title = ytVideo.Title
+	video.Description = ytVideo.Description
+	video.Duration = ytVideo.Duration
+	if thumbnailURL := largestYouTubeThumbnail(ytVideo.Thumbnails); thumbnailURL != "" {
+		video.ThumbnailURL = &thumbnailURL
+	}
+	video.TranscodeStatus = string(transcodeStatusProcessing)
+
+	video, err = cfg.db.CreateVideo(video)
+	if err != nil {
+		os.Remove(tempFile.Name())
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create video in database", err)
+		return
+	}
+
+	cfg.jobQueue.enqueue(transcodeJob{videoID: video.ID, inputFilePath: tempFile.Name()})
+
+	respondWithJSON(w, http.StatusAccepted, video)
+}
+
+// highestQualityProgressiveFormat picks the best video+audio MP4 format, since
+// adaptive (video-only) formats would need a separate audio download+mux step
+// that the rest of the pipeline doesn't support.
+func highestQualityProgressiveFormat(formats youtube.FormatList) (*youtube.Format, error) {
+	progressive := formats.Type("video/mp4").WithAudioChannels()
+	if len(progressive) == 0 {
+		return nil, fmt.Errorf("no progressive mp4 formats available")
+	}
+	sort.Slice(progressive, func(i, j int) bool {
+		return progressive[i].Height > progressive[j].Height
+	})
+	return &progressive[0], nil
+}
+
+func largestYouTubeThumbnail(thumbnails youtube.Thumbnails) string {
+	if len(thumbnails) == 0 {
+		return ""
+	}
+	largest := thumbnails[0]
+	for _, t := range thumbnails[1:] {
+		if t.Width*t.Height > largest.Width*largest.Height {
+			largest = t
+		}
+	}
+	return largest.URL
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/google/uuid"
+)
+
+// handlerGetVideoStatus reports transcode progress for a video that was
+// enqueued by handlerUploadVideo, so clients can poll instead of blocking on
+// the upload request while ffmpeg builds the HLS ladder.
+func (cfg *apiConfig) handlerGetVideoStatus(w http.ResponseWriter, r *http.Request) {
+	videoIDString := r.PathValue("videoID")
+	videoID, err := uuid.Parse(videoIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	video, err := cfg.db.GetVideo(videoID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get video from database", err)
+		return
+	}
+	if video.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "User is not the owner of the video", nil)
+		return
+	}
+
+	status := video.TranscodeStatus
+	if status == "" {
+		status = string(transcodeStatusReady) // uploads predating the async pipeline have no status recorded
+	}
+
+	// Once the video is ready, hand back a playable URL too - otherwise
+	// polling only ever tells the client "ready" with no way to fetch it.
+	videoURL := ""
+	if status == string(transcodeStatusReady) {
+		signedVideo, err := cfg.dbVideoToSignedVideo(r.Context(), video)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't generate video URL", err)
+			return
+		}
+		if signedVideo.VideoURL != nil {
+			videoURL = *signedVideo.VideoURL
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, struct {
+		Status   string `json:"status"`
+		VideoURL string `json:"video_url,omitempty"`
+	}{Status: status, VideoURL: videoURL})
+}
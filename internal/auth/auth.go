@@ -0,0 +1,54 @@
+// Package auth holds the bearer-token plumbing every handler in this
+// repo uses to authenticate a request: pulling the token out of the
+// Authorization header and validating it as a JWT signed with the
+// server's secret.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// GetBearerToken extracts the token from an "Authorization: Bearer <token>"
+// header.
+func GetBearerToken(headers http.Header) (string, error) {
+	authHeader := headers.Get("Authorization")
+	if authHeader == "" {
+		return "", errors.New("no Authorization header included in request")
+	}
+
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || token == "" {
+		return "", errors.New("malformed Authorization header")
+	}
+	return token, nil
+}
+
+// ValidateJWT parses and verifies tokenString against tokenSecret, returning
+// the user ID stored in its subject claim.
+func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	claims := jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(tokenSecret), nil
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("couldn't parse token: %w", err)
+	}
+	if !token.Valid {
+		return uuid.Nil, errors.New("invalid token")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("couldn't parse subject as user ID: %w", err)
+	}
+	return userID, nil
+}
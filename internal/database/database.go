@@ -0,0 +1,65 @@
+// Package database is Tubely's persistence layer: a single JSON file on disk
+// guarded by a mutex. There's no real RDBMS here, just enough structure for
+// the HTTP layer to load/save video rows without knowing the file format.
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Client loads dbSchema into memory on NewClient and flushes it back to disk
+// after every write. mu is a pointer so Client can be passed around by value
+// (as cfg.db already is) without copying the lock.
+type Client struct {
+	mu     *sync.RWMutex
+	path   string
+	schema dbSchema
+}
+
+type dbSchema struct {
+	Videos map[uuid.UUID]Video `json:"videos"`
+}
+
+// NewClient loads path into memory, creating an empty database file there if
+// one doesn't exist yet.
+func NewClient(path string) (Client, error) {
+	c := Client{
+		mu:     &sync.RWMutex{},
+		path:   path,
+		schema: dbSchema{Videos: map[uuid.UUID]Video{}},
+	}
+	if err := c.load(); err != nil {
+		return Client{}, err
+	}
+	return c, nil
+}
+
+func (c Client) load() error {
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c.save()
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't read database file: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, &c.schema)
+}
+
+func (c Client) save() error {
+	data, err := json.MarshalIndent(c.schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal database: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("couldn't write database file: %w", err)
+	}
+	return nil
+}
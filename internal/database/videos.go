@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Video is a row in the videos table. It embeds VideoMetadata so that
+// handlers which only need title/description/owner can still pass a bare
+// Video around without unpacking it.
+type Video struct {
+	VideoMetadata
+}
+
+// VideoMetadata holds every field on a video row, including the structured
+// storage references (VideoBucket/VideoKey/VideoManifestKey) that replaced
+// the old "bucket,key" VideoURL hack, the transcode/thumbnail bookkeeping the
+// HLS pipeline needs, and the duration copied over from YouTube ingestion.
+type VideoMetadata struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// VideoBucket/VideoKey locate the uploaded source (or, for a single
+	// fast-start MP4, the playable file itself) in the configured FileStore.
+	// VideoManifestKey is set instead once a video has been transcoded to
+	// HLS, and takes precedence in dbVideoToSignedVideo.
+	VideoBucket      string  `json:"video_bucket"`
+	VideoKey         string  `json:"video_key"`
+	VideoManifestKey *string `json:"video_manifest_key,omitempty"`
+	VideoURL         *string `json:"video_url"`
+
+	// ThumbnailKey is the poster's location in the FileStore; ThumbnailURL is
+	// the presigned URL rebuilt from it on every read, the same way VideoURL
+	// is rebuilt from VideoKey/VideoManifestKey.
+	ThumbnailKey    *string `json:"thumbnail_key,omitempty"`
+	ThumbnailURL    *string `json:"thumbnail_url"`
+	ThumbnailWidth  int     `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int     `json:"thumbnail_height,omitempty"`
+
+	// TranscodeStatus mirrors the transcodeStatus lifecycle so clients can
+	// poll GET /api/videos/{id}/status while the HLS job runs.
+	TranscodeStatus string `json:"transcode_status,omitempty"`
+
+	// Duration is populated for videos ingested from YouTube; direct uploads
+	// leave it zero.
+	Duration time.Duration `json:"duration,omitempty"`
+
+	CreateVideoParams
+}
+
+// CreateVideoParams is the subset of VideoMetadata a caller supplies when
+// creating a video; CreateVideo sets the timestamps, and everything else
+// (storage refs, transcode status) is filled in by the upload/ingestion
+// handlers.
+type CreateVideoParams struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+// CreateVideo assigns an ID if the caller hasn't already set one (as
+// handlerCreateVideoFromYouTube does) and always sets CreatedAt/UpdatedAt to
+// the current time.
+func (c Client) CreateVideo(video Video) (Video, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if video.ID == uuid.Nil {
+		video.ID = uuid.New()
+	}
+	now := time.Now()
+	video.CreatedAt = now
+	video.UpdatedAt = now
+
+	c.schema.Videos[video.ID] = video
+	if err := c.save(); err != nil {
+		return Video{}, fmt.Errorf("couldn't save new video: %w", err)
+	}
+	return video, nil
+}
+
+// GetVideo returns a single video by ID.
+func (c Client) GetVideo(id uuid.UUID) (Video, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	video, ok := c.schema.Videos[id]
+	if !ok {
+		return Video{}, fmt.Errorf("video %s not found", id)
+	}
+	return video, nil
+}
+
+// GetVideos returns every video, used by the startup storage-column backfill.
+func (c Client) GetVideos() ([]Video, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	videos := make([]Video, 0, len(c.schema.Videos))
+	for _, video := range c.schema.Videos {
+		videos = append(videos, video)
+	}
+	return videos, nil
+}
+
+// UpdateVideo overwrites the stored row for video.ID, bumping UpdatedAt.
+func (c Client) UpdateVideo(video Video) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.schema.Videos[video.ID]; !ok {
+		return fmt.Errorf("video %s not found", video.ID)
+	}
+	video.UpdatedAt = time.Now()
+	c.schema.Videos[video.ID] = video
+	return c.save()
+}
+
+// DeleteVideo removes a video row.
+func (c Client) DeleteVideo(id uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.schema.Videos[id]; !ok {
+		return fmt.Errorf("video %s not found", id)
+	}
+	delete(c.schema.Videos, id)
+	return c.save()
+}
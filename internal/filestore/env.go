@@ -0,0 +1,18 @@
+package filestore
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// NewFromEnv picks the FileStore backend based on FILE_STORE_BACKEND
+// ("s3", the default, or "local"), so main can wire it up without every
+// caller needing to know the decision logic. Local mode lets a dev run
+// Tubely without AWS credentials.
+func NewFromEnv(s3Client *s3.Client, s3Bucket, assetsRoot, baseURL string, signingKey []byte) FileStore {
+	if os.Getenv("FILE_STORE_BACKEND") == "local" {
+		return NewLocalDiskFileStore(assetsRoot, baseURL, signingKey)
+	}
+	return NewS3FileStore(s3Client, s3Bucket)
+}
@@ -0,0 +1,21 @@
+// Package filestore abstracts the blob storage backend behind the handlers
+// so Tubely can run against S3 in production and a local disk store in dev,
+// without either the HTTP layer or the transcode pipeline knowing which one
+// is active.
+package filestore
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// FileStore is implemented by S3FileStore and LocalDiskFileStore. Keys are
+// always forward-slash-separated paths like "videos/<uuid>/hls/master.m3u8",
+// regardless of backend.
+type FileStore interface {
+	PutObject(ctx context.Context, key string, body io.Reader, contentType string) error
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+	PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	DeleteObject(ctx context.Context, key string) error
+}
@@ -0,0 +1,61 @@
+package filestore
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifySignedURL_ValidSignature(t *testing.T) {
+	s := NewLocalDiskFileStore("", "http://localhost", []byte("test-signing-key"))
+
+	exp := time.Now().Add(time.Hour).Unix()
+	sig := s.sign("videos/abc/hls/master.m3u8", exp)
+
+	if !s.VerifySignedURL("videos/abc/hls/master.m3u8", sig, strconv.FormatInt(exp, 10)) {
+		t.Fatal("expected a freshly signed URL to verify")
+	}
+}
+
+func TestVerifySignedURL_TamperedSignature(t *testing.T) {
+	s := NewLocalDiskFileStore("", "http://localhost", []byte("test-signing-key"))
+
+	exp := time.Now().Add(time.Hour).Unix()
+	sig := s.sign("videos/abc/hls/master.m3u8", exp)
+
+	if s.VerifySignedURL("videos/abc/hls/master.m3u8", sig+"tampered", strconv.FormatInt(exp, 10)) {
+		t.Fatal("expected a tampered signature to fail verification")
+	}
+}
+
+func TestVerifySignedURL_WrongKey(t *testing.T) {
+	s := NewLocalDiskFileStore("", "http://localhost", []byte("test-signing-key"))
+
+	exp := time.Now().Add(time.Hour).Unix()
+	sig := s.sign("videos/abc/hls/master.m3u8", exp)
+
+	if s.VerifySignedURL("videos/other/hls/master.m3u8", sig, strconv.FormatInt(exp, 10)) {
+		t.Fatal("expected a signature for a different key to fail verification")
+	}
+}
+
+func TestVerifySignedURL_Expired(t *testing.T) {
+	s := NewLocalDiskFileStore("", "http://localhost", []byte("test-signing-key"))
+
+	exp := time.Now().Add(-time.Minute).Unix()
+	sig := s.sign("videos/abc/hls/master.m3u8", exp)
+
+	if s.VerifySignedURL("videos/abc/hls/master.m3u8", sig, strconv.FormatInt(exp, 10)) {
+		t.Fatal("expected an expired exp to fail verification")
+	}
+}
+
+func TestVerifySignedURL_MalformedExp(t *testing.T) {
+	s := NewLocalDiskFileStore("", "http://localhost", []byte("test-signing-key"))
+
+	sig := s.sign("videos/abc/hls/master.m3u8", time.Now().Add(time.Hour).Unix())
+
+	if s.VerifySignedURL("videos/abc/hls/master.m3u8", sig, "not-a-number") {
+		t.Fatal("expected a non-numeric exp to fail verification")
+	}
+}
@@ -0,0 +1,77 @@
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3FileStore wraps the AWS SDK v2 client used by the rest of Tubely today.
+// Puts go through an s3manager.Uploader so large videos stream as multipart
+// uploads instead of buffering a single PutObject body in memory.
+type S3FileStore struct {
+	client        *s3.Client
+	presignClient *s3.PresignClient
+	uploader      *manager.Uploader
+	bucket        string
+}
+
+func NewS3FileStore(client *s3.Client, bucket string) *S3FileStore {
+	return &S3FileStore{
+		client:        client,
+		presignClient: s3.NewPresignClient(client),
+		uploader:      manager.NewUploader(client),
+		bucket:        bucket,
+	}
+}
+
+func (s *S3FileStore) PutObject(ctx context.Context, key string, body io.Reader, contentType string) error {
+	_, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't upload %q to S3: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3FileStore) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get %q from S3: %w", key, err)
+	}
+	return out.Body, nil
+}
+
+func (s *S3FileStore) PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	presigned, err := s.presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("couldn't generate presigned URL for %q: %w", key, err)
+	}
+	return presigned.URL, nil
+}
+
+func (s *S3FileStore) DeleteObject(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete %q from S3: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// respondWithJSON writes payload as the JSON response body with the given
+// status code. Used by every handler in this package instead of each
+// reimplementing marshal-and-write.
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("couldn't marshal JSON response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// respondWithError wraps msg in {"error": msg} and logs the underlying err,
+// if any. 5XX responses get an extra log line since those indicate a bug on
+// our side rather than a bad request.
+func respondWithError(w http.ResponseWriter, code int, msg string, err error) {
+	if err != nil {
+		log.Println(err)
+	}
+	if code > 499 {
+		log.Printf("responding with 5XX error: %s", msg)
+	}
+	respondWithJSON(w, code, struct {
+		Error string `json:"error"`
+	}{Error: msg})
+}
+
+// getAssetPath generates a random filename for an uploaded asset, keyed off
+// its media type so the extension matches the content (e.g. "image/jpeg" ->
+// ".jpeg").
+func getAssetPath(mediaType string) string {
+	key := make([]byte, 32)
+	rand.Read(key)
+	id := base64.RawURLEncoding.EncodeToString(key)
+	return id + mediaTypeToExt(mediaType)
+}
+
+// mediaTypeToExt turns a MIME type's subtype into a file extension, falling
+// back to ".bin" for anything that isn't a well-formed "type/subtype" string.
+func mediaTypeToExt(mediaType string) string {
+	parts := strings.Split(mediaType, "/")
+	if len(parts) != 2 {
+		return ".bin"
+	}
+	return "." + parts[1]
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/filestore"
+)
+
+// apiConfig carries every dependency the handlers in this package close
+// over: the JSON-file database, the configured blob store (S3 in prod, local
+// disk in dev, picked by filestore.NewFromEnv), the async transcode job
+// queue, and the upload-progress SSE hub.
+type apiConfig struct {
+	db         database.Client
+	jwtSecret  string
+	fileStore  filestore.FileStore
+	jobQueue   *videoJobQueue
+	progress   *progressHub
+	assetsRoot string
+}
+
+func main() {
+	dbPath := envOrDefault("DB_PATH", "tubely.json")
+	db, err := database.NewClient(dbPath)
+	if err != nil {
+		log.Fatalf("couldn't create database client: %v", err)
+	}
+
+	assetsRoot := envOrDefault("ASSETS_ROOT", "assets")
+	if err := os.MkdirAll(assetsRoot, 0755); err != nil {
+		log.Fatalf("couldn't create assets root: %v", err)
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("couldn't load AWS config: %v", err)
+	}
+	s3Client := s3.NewFromConfig(awsCfg)
+
+	cfg := &apiConfig{
+		db:        db,
+		jwtSecret: os.Getenv("JWT_SECRET"),
+		fileStore: filestore.NewFromEnv(
+			s3Client,
+			os.Getenv("S3_BUCKET"),
+			assetsRoot,
+			envOrDefault("BASE_URL", "http://localhost:8080"),
+			[]byte(os.Getenv("ASSETS_SIGNING_KEY")),
+		),
+		progress:   newProgressHub(),
+		assetsRoot: assetsRoot,
+	}
+	cfg.jobQueue = newVideoJobQueue(cfg, 4)
+
+	if err := cfg.backfillVideoStorageColumns(); err != nil {
+		log.Fatalf("couldn't backfill video storage columns: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/videos/{videoID}/upload", cfg.handlerUploadVideo)
+	mux.HandleFunc("POST /api/videos/{videoID}/thumbnail", cfg.handlerUploadThumbnail)
+	mux.HandleFunc("GET /api/videos/{videoID}/status", cfg.handlerGetVideoStatus)
+	mux.HandleFunc("GET /api/videos/{videoID}/upload-progress", cfg.handlerVideoUploadProgress)
+	mux.HandleFunc("POST /api/videos/from_youtube", cfg.handlerCreateVideoFromYouTube)
+	mux.HandleFunc("GET /assets/signed/{key...}", cfg.handlerGetSignedAsset)
+
+	addr := ":" + envOrDefault("PORT", "8080")
+	log.Printf("serving on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// progressPhase tracks which leg of the upload+transcode pipeline a Progress
+// sample describes, since BytesRead/TotalBytes only cover the copy of the
+// already-received upload into the handler's temp file and go quiet for the
+// (often much longer) transcode+S3-upload leg that follows it.
+type progressPhase string
+
+const (
+	progressPhaseUploading   progressPhase = "uploading"
+	progressPhaseTranscoding progressPhase = "transcoding"
+	progressPhaseReady       progressPhase = "ready"
+	progressPhaseError       progressPhase = "error"
+)
+
+// Progress is one upload/transcode progress sample, emitted as the uploaded
+// file is copied or as the transcode job changes phase, and broadcast to every
+// subscriber of that video's SSE stream.
+type Progress struct {
+	VideoID    uuid.UUID     `json:"video_id"`
+	Phase      progressPhase `json:"phase"`
+	BytesRead  int64         `json:"bytes_read"`
+	TotalBytes int64         `json:"total_bytes"`
+	Percent    float64       `json:"percent"`
+}
+
+// progressReader decorates an io.Reader, calling onProgress after every Read
+// with the running byte count and the expected total (exp). Wrapping the
+// multipart file reader this way lets the SSE endpoint show how far the copy
+// of the uploaded file has got without the handler having to track it inline.
+type progressReader struct {
+	reader     io.Reader
+	total      int64
+	exp        int64
+	onProgress func(total, exp int64)
+}
+
+func newProgressReader(r io.Reader, exp int64, onProgress func(total, exp int64)) *progressReader {
+	return &progressReader{reader: r, exp: exp, onProgress: onProgress}
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	pr.total += int64(n)
+	if pr.onProgress != nil {
+		pr.onProgress(pr.total, pr.exp)
+	}
+	return n, err
+}
+
+// progressHub fans upload/transcode progress out to every handler subscribed
+// to a given video's SSE stream. cfg.progress is populated in main() as
+// newProgressHub(). Sends are non-blocking: a subscriber that isn't keeping up
+// just misses samples rather than stalling the upload.
+//
+// Subscribers are keyed by a per-subscription token, not just videoID, so two
+// concurrent subscribers to the same video (e.g. two browser tabs) each get
+// their own channel instead of the second subscribe overwriting the first's
+// entry and one side's unsubscribe closing the channel out from under the
+// other.
+type progressHub struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[uuid.UUID]map[uint64]chan Progress
+}
+
+func newProgressHub() *progressHub {
+	return &progressHub{subs: make(map[uuid.UUID]map[uint64]chan Progress)}
+}
+
+// subscribe registers a new listener for videoID and returns its channel
+// along with an unsubscribe func bound to this specific subscription.
+func (h *progressHub) subscribe(videoID uuid.UUID) (ch chan Progress, unsubscribe func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	ch = make(chan Progress, 16)
+	if h.subs[videoID] == nil {
+		h.subs[videoID] = make(map[uint64]chan Progress)
+	}
+	h.subs[videoID][id] = ch
+
+	unsubscribe = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if subs, ok := h.subs[videoID]; ok {
+			if sub, ok := subs[id]; ok {
+				close(sub)
+				delete(subs, id)
+			}
+			if len(subs) == 0 {
+				delete(h.subs, videoID)
+			}
+		}
+	}
+	return ch, unsubscribe
+}
+
+func (h *progressHub) publish(p Progress) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, ch := range h.subs[p.VideoID] {
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
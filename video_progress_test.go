@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestProgressHub_PublishDeliversToSubscriber(t *testing.T) {
+	hub := newProgressHub()
+	videoID := uuid.New()
+
+	ch, unsubscribe := hub.subscribe(videoID)
+	defer unsubscribe()
+
+	hub.publish(Progress{VideoID: videoID, Phase: progressPhaseUploading})
+
+	select {
+	case p := <-ch:
+		if p.Phase != progressPhaseUploading {
+			t.Fatalf("got phase %q, want %q", p.Phase, progressPhaseUploading)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for published progress")
+	}
+}
+
+func TestProgressHub_PublishIgnoresOtherVideos(t *testing.T) {
+	hub := newProgressHub()
+	videoID := uuid.New()
+	otherID := uuid.New()
+
+	ch, unsubscribe := hub.subscribe(videoID)
+	defer unsubscribe()
+
+	hub.publish(Progress{VideoID: otherID, Phase: progressPhaseUploading})
+
+	select {
+	case p := <-ch:
+		t.Fatalf("unexpected progress for unrelated video: %+v", p)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestProgressHub_IndependentSubscribersSameVideo guards against a past bug
+// where subscribers were keyed only by videoID, so two concurrent
+// subscriptions to the same video shared one map entry and one side's
+// unsubscribe closed the channel out from under the other.
+func TestProgressHub_IndependentSubscribersSameVideo(t *testing.T) {
+	hub := newProgressHub()
+	videoID := uuid.New()
+
+	chA, unsubscribeA := hub.subscribe(videoID)
+	chB, unsubscribeB := hub.subscribe(videoID)
+	defer unsubscribeB()
+
+	unsubscribeA()
+
+	hub.publish(Progress{VideoID: videoID, Phase: progressPhaseReady})
+
+	select {
+	case p, ok := <-chB:
+		if !ok {
+			t.Fatal("subscriber B's channel was closed by subscriber A's unsubscribe")
+		}
+		if p.Phase != progressPhaseReady {
+			t.Fatalf("got phase %q, want %q", p.Phase, progressPhaseReady)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for published progress")
+	}
+
+	if _, ok := <-chA; ok {
+		t.Fatal("expected subscriber A's channel to be closed after its own unsubscribe")
+	}
+}
+
+func TestProgressHub_PublishIsNonBlockingWhenSubscriberIsFull(t *testing.T) {
+	hub := newProgressHub()
+	videoID := uuid.New()
+
+	_, unsubscribe := hub.subscribe(videoID)
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			hub.publish(Progress{VideoID: videoID, Phase: progressPhaseUploading})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked on a full subscriber channel")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// backfillVideoStorageColumns is a one-shot startup migration (call it once
+// from main after cfg.db is wired up) that parses any pre-migration
+// "bucket,key" VideoURL values left over from before VideoBucket/VideoKey
+// were added to database.Video, and writes them into the new structured
+// fields. Videos that already have a VideoKey are left untouched.
+func (cfg *apiConfig) backfillVideoStorageColumns() error {
+	videos, err := cfg.db.GetVideos()
+	if err != nil {
+		return fmt.Errorf("couldn't list videos for storage backfill: %w", err)
+	}
+
+	for _, video := range videos {
+		if video.VideoKey != "" || video.VideoURL == nil {
+			continue
+		}
+		parts := strings.SplitN(*video.VideoURL, ",", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		video.VideoBucket = parts[0]
+		video.VideoKey = parts[1]
+		if err := cfg.db.UpdateVideo(video); err != nil {
+			return fmt.Errorf("couldn't backfill storage columns for video %s: %w", video.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// thumbnailURLTTL is the TTL handed to PresignGetURL each time a thumbnail is
+// re-signed (see signThumbnailURL); it's long relative to the video's own
+// presign TTL since a poster is cheap to keep serving and gets embedded in
+// list views, but the key itself is what's persisted, so nothing expires
+// permanently the way a stored presigned URL would.
+const thumbnailURLTTL = 7 * 24 * time.Hour
+
+// defaultThumbnailWidth/Height is the 16:9 poster size used for landscape and
+// square-ish sources. Portrait (9:16) sources get the dimensions swapped.
+const (
+	defaultThumbnailWidth  = 320
+	defaultThumbnailHeight = 180
+)
+
+// generateVideoThumbnail shells out to ffmpeg to grab a single frame at
+// atSeconds into the source video, scaled to width x height, and returns the
+// path to the resulting JPEG. The caller is responsible for removing it.
+func generateVideoThumbnail(inputPath string, atSeconds float64, width, height int) (string, error) {
+	outputPath := fmt.Sprintf("%s-thumbnail.jpg", inputPath)
+
+	cmd := exec.Command("ffmpeg",
+		"-ss", fmt.Sprintf("%.2f", atSeconds),
+		"-i", inputPath,
+		"-vframes", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d", width, height),
+		"-f", "image2",
+		outputPath,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ffmpeg thumbnail extraction failed: %s, %w", stderr.String(), err)
+	}
+
+	fileInfo, err := os.Stat(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("couldn't stat thumbnail file: %w", err)
+	}
+	if fileInfo.Size() == 0 {
+		return "", fmt.Errorf("thumbnail file is empty")
+	}
+
+	return outputPath, nil
+}
+
+// thumbnailDimensionsFor picks the poster size for an aspect ratio bucket as
+// returned by getVideoAspectRatio: portrait sources get a 180x320 poster,
+// everything else gets the standard 320x180 16:9 poster.
+func thumbnailDimensionsFor(aspectRatio string) (width, height int) {
+	if aspectRatio == "9:16" {
+		return defaultThumbnailHeight, defaultThumbnailWidth
+	}
+	return defaultThumbnailWidth, defaultThumbnailHeight
+}
+
+// uploadVideoThumbnail extracts a poster frame from inputFilePath and uploads
+// it via cfg.fileStore next to the video, returning its storage key (not a
+// presigned URL - the caller stores the key on database.Video.ThumbnailKey
+// and dbVideoToSignedVideo re-signs it on every read, same as VideoKey) and
+// the dimensions it was scaled to.
+func (cfg *apiConfig) uploadVideoThumbnail(videoID uuid.UUID, inputFilePath string) (thumbnailKey string, width, height int, err error) {
+	aspectRatio, err := getVideoAspectRatio(inputFilePath)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("couldn't get video aspect ratio: %w", err)
+	}
+	width, height = thumbnailDimensionsFor(aspectRatio)
+
+	thumbnailPath, err := generateVideoThumbnail(inputFilePath, 1.0, width, height)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	defer os.Remove(thumbnailPath)
+
+	thumbnailFile, err := os.Open(thumbnailPath)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("couldn't open thumbnail file: %w", err)
+	}
+	defer thumbnailFile.Close()
+
+	key := fmt.Sprintf("videos/%s/thumbnail.jpg", videoID)
+	ctx := context.Background()
+	if err := cfg.fileStore.PutObject(ctx, key, thumbnailFile, "image/jpeg"); err != nil {
+		return "", 0, 0, fmt.Errorf("couldn't upload thumbnail: %w", err)
+	}
+
+	return key, width, height, nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
+	"github.com/google/uuid"
+)
+
+// transcodeStatus mirrors the lifecycle of an async HLS transcode job and is
+// stored on database.Video as TranscodeStatus so clients can poll for it.
+type transcodeStatus string
+
+const (
+	transcodeStatusProcessing transcodeStatus = "processing"
+	transcodeStatusReady      transcodeStatus = "ready"
+	transcodeStatusError      transcodeStatus = "error"
+)
+
+// hlsRendition describes one rung of the adaptive bitrate ladder.
+type hlsRendition struct {
+	name         string // e.g. "240p", used as the output subdirectory
+	width        int
+	height       int
+	videoBitrate string // ffmpeg -b:v value, e.g. "800k"
+	audioBitrate string // ffmpeg -b:a value, e.g. "96k"
+}
+
+// defaultHLSLadder is the rendition set used for every upload. Any rendition
+// taller than the source is skipped; see transcodeToHLS.
+var defaultHLSLadder = []hlsRendition{
+	{name: "240p", width: 426, height: 240, videoBitrate: "400k", audioBitrate: "64k"},
+	{name: "480p", width: 854, height: 480, videoBitrate: "800k", audioBitrate: "96k"},
+	{name: "720p", width: 1280, height: 720, videoBitrate: "2500k", audioBitrate: "128k"},
+	{name: "1080p", width: 1920, height: 1080, videoBitrate: "5000k", audioBitrate: "128k"},
+}
+
+// videoJobQueue is an in-process worker pool that runs transcode jobs off the
+// request goroutine so handlerUploadVideo can return immediately. cfg.jobQueue
+// is populated in main() with newVideoJobQueue(cfg, 4).
+type videoJobQueue struct {
+	jobs chan transcodeJob
+}
+
+type transcodeJob struct {
+	videoID       uuid.UUID
+	inputFilePath string
+}
+
+func newVideoJobQueue(cfg *apiConfig, workers int) *videoJobQueue {
+	q := &videoJobQueue{jobs: make(chan transcodeJob, 64)}
+	for i := 0; i < workers; i++ {
+		go q.worker(cfg)
+	}
+	return q
+}
+
+func (q *videoJobQueue) enqueue(job transcodeJob) {
+	q.jobs <- job
+}
+
+func (q *videoJobQueue) worker(cfg *apiConfig) {
+	for job := range q.jobs {
+		cfg.runTranscodeJob(job)
+	}
+}
+
+// runTranscodeJob drives one video through the full ladder + upload +
+// database update, recording a terminal status either way so the polling
+// status endpoint always has something to report.
+func (cfg *apiConfig) runTranscodeJob(job transcodeJob) {
+	defer os.Remove(job.inputFilePath)
+
+	video, err := cfg.db.GetVideo(job.videoID)
+	if err != nil {
+		log.Printf("transcode job %s: couldn't load video: %v", job.videoID, err)
+		return
+	}
+
+	if video.ThumbnailKey == nil {
+		thumbnailKey, width, height, err := cfg.uploadVideoThumbnail(job.videoID, job.inputFilePath)
+		if err == nil {
+			video.ThumbnailKey = &thumbnailKey
+			video.ThumbnailWidth = width
+			video.ThumbnailHeight = height
+		}
+		// A failed thumbnail extraction shouldn't block the video itself from
+		// becoming ready; handlerUploadThumbnail remains available as a fallback.
+	}
+
+	outputDir, manifestKey, err := cfg.transcodeToHLS(job.videoID, job.inputFilePath)
+	if err != nil {
+		cfg.failTranscodeJob(video, fmt.Errorf("couldn't transcode to HLS: %w", err))
+		return
+	}
+	defer os.RemoveAll(outputDir)
+
+	if err := cfg.uploadHLSTree(outputDir, job.videoID); err != nil {
+		cfg.failTranscodeJob(video, fmt.Errorf("couldn't upload HLS tree: %w", err))
+		return
+	}
+
+	video.VideoManifestKey = &manifestKey
+	video.TranscodeStatus = string(transcodeStatusReady)
+	if err := cfg.db.UpdateVideo(video); err != nil {
+		// The transcode itself succeeded, but without this write the video is
+		// stuck reporting "processing" forever with no diagnostic trail.
+		log.Printf("transcode job %s: couldn't save ready status: %v", video.ID, err)
+	}
+	cfg.progress.publish(Progress{VideoID: job.videoID, Phase: progressPhaseReady})
+}
+
+// failTranscodeJob marks video as errored and logs (rather than discards)
+// any failure to persist that, since a failed job has no HTTP response or
+// caller to surface the error to - a silently-dropped UpdateVideo error here
+// leaves the video stuck at "processing" forever with nothing in the logs to
+// explain why.
+func (cfg *apiConfig) failTranscodeJob(video database.Video, cause error) {
+	log.Printf("transcode job %s: %v", video.ID, cause)
+	video.TranscodeStatus = string(transcodeStatusError)
+	if err := cfg.db.UpdateVideo(video); err != nil {
+		log.Printf("transcode job %s: couldn't save error status: %v", video.ID, err)
+	}
+	cfg.progress.publish(Progress{VideoID: video.ID, Phase: progressPhaseError})
+}
+
+// transcodeToHLS re-encodes inputFilePath into the adaptive bitrate ladder
+// plus a master playlist, all written under a fresh temp directory. Renditions
+// taller than the source are skipped rather than upscaled.
+func (cfg *apiConfig) transcodeToHLS(videoID uuid.UUID, inputFilePath string) (outputDir, manifestKey string, err error) {
+	sourceHeight, err := getVideoHeight(inputFilePath)
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't determine source height: %w", err)
+	}
+
+	outputDir, err = os.MkdirTemp("", "tubely-hls")
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't create hls output dir: %w", err)
+	}
+
+	var masterLines []string
+	masterLines = append(masterLines, "#EXTM3U", "#EXT-X-VERSION:3")
+
+	renditionCount := 0
+	for _, rendition := range defaultHLSLadder {
+		if rendition.height > sourceHeight {
+			continue
+		}
+		renditionDir := filepath.Join(outputDir, rendition.name)
+		if err := os.Mkdir(renditionDir, 0755); err != nil {
+			return "", "", fmt.Errorf("couldn't create rendition dir: %w", err)
+		}
+		if err := encodeHLSRendition(inputFilePath, renditionDir, rendition); err != nil {
+			return "", "", fmt.Errorf("couldn't encode %s rendition: %w", rendition.name, err)
+		}
+		masterLines = append(masterLines,
+			fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d", bandwidthFor(rendition), rendition.width, rendition.height),
+			fmt.Sprintf("%s/stream.m3u8", rendition.name),
+		)
+		renditionCount++
+	}
+
+	// A source shorter than our lowest rung (240p) would otherwise skip every
+	// rendition and produce a master playlist with zero #EXT-X-STREAM-INF
+	// entries - unplayable, but indistinguishable from success to the caller.
+	// Fail the job instead of publishing an empty ladder.
+	if renditionCount == 0 {
+		return "", "", fmt.Errorf("source height %dp is below the lowest rendition in the ladder", sourceHeight)
+	}
+
+	masterPath := filepath.Join(outputDir, "master.m3u8")
+	if err := os.WriteFile(masterPath, []byte(joinLines(masterLines)), 0644); err != nil {
+		return "", "", fmt.Errorf("couldn't write master playlist: %w", err)
+	}
+
+	manifestKey = filepath.Join("videos", videoID.String(), "hls", "master.m3u8")
+	return outputDir, manifestKey, nil
+}
+
+func encodeHLSRendition(inputFilePath, renditionDir string, r hlsRendition) error {
+	cmd := exec.Command("ffmpeg",
+		"-i", inputFilePath,
+		"-vf", fmt.Sprintf("scale=w=%d:h=%d:force_original_aspect_ratio=decrease", r.width, r.height),
+		"-c:v", "h264", "-b:v", r.videoBitrate,
+		"-c:a", "aac", "-b:a", r.audioBitrate,
+		"-hls_time", "6",
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(renditionDir, "segment%03d.ts"),
+		filepath.Join(renditionDir, "stream.m3u8"),
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %s, %w", stderr.String(), err)
+	}
+	return nil
+}
+
+func bandwidthFor(r hlsRendition) int {
+	videoKbps := parseKbps(r.videoBitrate)
+	audioKbps := parseKbps(r.audioBitrate)
+	return (videoKbps + audioKbps) * 1000
+}
+
+func parseKbps(bitrate string) int {
+	var kbps int
+	fmt.Sscanf(bitrate, "%dk", &kbps)
+	return kbps
+}
+
+func joinLines(lines []string) string {
+	out := ""
+	for _, line := range lines {
+		out += line + "\n"
+	}
+	return out
+}
+
+// getVideoHeight probes the source's real pixel height; getVideoAspectRatio
+// only buckets into 9:16/16:9/other, which isn't enough to size the ladder.
+func getVideoHeight(filePath string) (int, error) {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=height",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		filePath,
+	)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("ffprobe height probe failed: %w", err)
+	}
+	var height int
+	if _, err := fmt.Sscanf(out.String(), "%d", &height); err != nil {
+		return 0, fmt.Errorf("couldn't parse height: %w", err)
+	}
+	return height, nil
+}
+
+// uploadHLSTree walks the local HLS output directory and puts every file
+// (master playlist, per-rendition playlists, and .ts segments) to cfg.fileStore
+// under videos/<uuid>/hls/, preserving the rendition subdirectories.
+func (cfg *apiConfig) uploadHLSTree(outputDir string, videoID uuid.UUID) error {
+	return filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(filepath.Join("videos", videoID.String(), "hls", relPath))
+		contentType := "application/octet-stream"
+		switch filepath.Ext(path) {
+		case ".m3u8":
+			contentType = "application/vnd.apple.mpegurl"
+		case ".ts":
+			contentType = "video/mp2t"
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return cfg.fileStore.PutObject(context.Background(), key, file, contentType)
+	})
+}